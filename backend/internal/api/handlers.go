@@ -35,7 +35,7 @@ func SteamCallbackHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	valid, err := auth.VerifySteamResponse(queryParams)
+	valid, err := auth.GetVerifier().Verify(r.Context(), queryParams)
 	if err != nil || !valid {
 		log.Printf("Steam verification failed: %v", err)
 		redirectWithError(w, r, "verification_failed")
@@ -184,3 +184,114 @@ func GameCacheStatsHandler(w http.ResponseWriter, r *http.Request) {
 
 	json.NewEncoder(w).Encode(stats)
 }
+
+// authenticatedSteamID 从 steam_token cookie 中解析出当前登录用户的 SteamID
+func authenticatedSteamID(r *http.Request) (string, error) {
+	cookie, err := r.Cookie("steam_token")
+	if err != nil {
+		return "", fmt.Errorf("not authenticated")
+	}
+
+	claims, err := auth.ParseJWT(cookie.Value)
+	if err != nil {
+		return "", fmt.Errorf("invalid session: %w", err)
+	}
+
+	return claims.SteamID, nil
+}
+
+// requireOwnSteamID 校验请求中的 {steamid} 是否就是当前登录用户，不是则写入 401
+func requireOwnSteamID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	steamID := r.PathValue("steamid")
+
+	authSteamID, err := authenticatedSteamID(r)
+	if err != nil || authSteamID != steamID {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(models.ErrorResponse{Error: "unauthorized"})
+		return "", false
+	}
+
+	return steamID, true
+}
+
+// ResolveVanityHandler 将 Steam 自定义 URL 解析为 64 位 SteamID
+func ResolveVanityHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vanity := r.URL.Query().Get("vanity")
+	if vanity == "" {
+		http.Error(w, "vanity parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	steamService := services.GetSteamService()
+	steamID, err := steamService.ResolveVanity(vanity)
+	if err != nil {
+		log.Printf("Failed to resolve vanity URL: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to resolve vanity URL: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"steamId": steamID})
+}
+
+// GameSuggestHandler 根据前缀返回输入提示（仅 name + appid，供前端自动完成使用）
+func GameSuggestHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	prefix := r.URL.Query().Get("prefix")
+	if prefix == "" {
+		json.NewEncoder(w).Encode([]models.SteamApp{})
+		return
+	}
+
+	limit := 10
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 && parsed <= 50 {
+			limit = parsed
+		}
+	}
+
+	apps := services.GetGameIndex().Suggest(prefix, limit)
+	json.NewEncoder(w).Encode(apps)
+}
+
+// UserFriendsHandler 返回当前登录用户的好友列表
+func UserFriendsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	steamID, ok := requireOwnSteamID(w, r)
+	if !ok {
+		return
+	}
+
+	steamService := services.GetSteamService()
+	friends, err := steamService.GetFriends(steamID)
+	if err != nil {
+		log.Printf("Failed to get friends: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to get friends: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(friends)
+}
+
+// UserLibraryHandler 返回当前登录用户的 Steam 游戏库
+func UserLibraryHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	steamID, ok := requireOwnSteamID(w, r)
+	if !ok {
+		return
+	}
+
+	steamService := services.GetSteamService()
+	games, err := steamService.GetOwnedGames(steamID)
+	if err != nil {
+		log.Printf("Failed to get owned games: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to get owned games: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(games)
+}
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"game-gallery-backend/internal/models"
+	"game-gallery-backend/internal/services"
+	"game-gallery-backend/internal/store"
+)
+
+// createSubscriptionRequest 是 POST /api/subscriptions 的请求体
+type createSubscriptionRequest struct {
+	AppID        int    `json:"appid"`
+	Threshold    int    `json:"threshold,omitempty"`
+	NotifyOnSale bool   `json:"notifyOnSale,omitempty"`
+	NotifyOnNews bool   `json:"notifyOnNews,omitempty"`
+	WebhookURL   string `json:"webhookUrl,omitempty"`
+}
+
+// CreateSubscriptionHandler 为当前登录用户创建一个游戏订阅
+func CreateSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	steamID, err := authenticatedSteamID(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	var req createSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.AppID <= 0 {
+		http.Error(w, "appid is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateWebhookURL(req.WebhookURL); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	subStore, err := store.GetSubscriptionStore()
+	if err != nil {
+		log.Printf("Failed to open subscription store: %v", err)
+		http.Error(w, "failed to create subscription", http.StatusInternalServerError)
+		return
+	}
+
+	sub := &store.Subscription{
+		ID:           store.NewSubscriptionID(),
+		SteamID:      steamID,
+		AppID:        req.AppID,
+		Threshold:    req.Threshold,
+		NotifyOnSale: req.NotifyOnSale,
+		NotifyOnNews: req.NotifyOnNews,
+		WebhookURL:   req.WebhookURL,
+		CreatedAt:    time.Now(),
+	}
+
+	if err := subStore.Create(sub); err != nil {
+		log.Printf("Failed to create subscription: %v", err)
+		http.Error(w, fmt.Sprintf("failed to create subscription: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(sub)
+}
+
+// ListSubscriptionsHandler 返回当前登录用户的全部订阅
+func ListSubscriptionsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	steamID, err := authenticatedSteamID(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	subStore, err := store.GetSubscriptionStore()
+	if err != nil {
+		log.Printf("Failed to open subscription store: %v", err)
+		http.Error(w, "failed to list subscriptions", http.StatusInternalServerError)
+		return
+	}
+
+	subs, err := subStore.ListBySteamID(steamID)
+	if err != nil {
+		log.Printf("Failed to list subscriptions: %v", err)
+		http.Error(w, fmt.Sprintf("failed to list subscriptions: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(subs)
+}
+
+// DeleteSubscriptionHandler 删除当前登录用户名下的一个订阅
+func DeleteSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	steamID, err := authenticatedSteamID(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "id parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	subStore, err := store.GetSubscriptionStore()
+	if err != nil {
+		log.Printf("Failed to open subscription store: %v", err)
+		http.Error(w, "failed to delete subscription", http.StatusInternalServerError)
+		return
+	}
+
+	if err := subStore.Delete(id, steamID); err != nil {
+		log.Printf("Failed to delete subscription: %v", err)
+		http.Error(w, fmt.Sprintf("failed to delete subscription: %v", err), http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.SuccessResponse{})
+}
+
+// SubscriptionStreamHandler 通过 Server-Sent Events 推送当前登录用户的订阅通知
+func SubscriptionStreamHandler(w http.ResponseWriter, r *http.Request) {
+	steamID, err := authenticatedSteamID(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	notifier := services.GetSSENotifier()
+	messages := notifier.Subscribe(steamID)
+	defer notifier.Unsubscribe(steamID, messages)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg := <-messages:
+			fmt.Fprintf(w, "data: %s\n\n", msg)
+			flusher.Flush()
+		}
+	}
+}
+
+// validateWebhookURL 拒绝非 http/https 协议、以及解析到回环/内网/链路本地地址的 webhook URL，
+// 防止订阅的 webhookUrl 被用作打向内部服务或云元数据接口（如 169.254.169.254）的 SSRF 跳板
+func validateWebhookURL(rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook url: %w", err)
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("webhook url must use http or https")
+	}
+
+	host := parsed.Hostname()
+	if host == "" {
+		return fmt.Errorf("webhook url must include a host")
+	}
+
+	disallowed, err := hostResolvesToDisallowedIP(host)
+	if err != nil {
+		return fmt.Errorf("could not validate webhook url: %w", err)
+	}
+	if disallowed {
+		return fmt.Errorf("webhook url must not point to a loopback, private or link-local address")
+	}
+
+	return nil
+}
+
+// hostResolvesToDisallowedIP 解析 host 并检查其任一 IP 是否为回环/内网/链路本地地址
+func hostResolvesToDisallowedIP(host string) (bool, error) {
+	ips := []net.IP{}
+
+	if ip := net.ParseIP(host); ip != nil {
+		ips = append(ips, ip)
+	} else {
+		resolved, err := net.LookupIP(host)
+		if err != nil {
+			return false, fmt.Errorf("failed to resolve host: %w", err)
+		}
+		ips = resolved
+	}
+
+	for _, ip := range ips {
+		if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisNonceStore 是 NonceStore 的 Redis 实现，用 SETNX 保证同一 nonce 只被接受一次，
+// 即便部署了多个后端实例
+type RedisNonceStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisNonceStore 创建一个基于 Redis 的 NonceStore
+func NewRedisNonceStore(client *redis.Client) *RedisNonceStore {
+	return &RedisNonceStore{client: client, prefix: "gg:openid:nonce:"}
+}
+
+// SeenBefore 实现 NonceStore 接口
+func (s *RedisNonceStore) SeenBefore(ctx context.Context, nonce, endpoint string) (bool, error) {
+	key := s.prefix + endpoint + "|" + nonce
+
+	// SetNX 在 key 不存在时写入并返回 true；返回 false 说明这个 nonce 已经出现过
+	ok, err := s.client.SetNX(ctx, key, 1, nonceExpiry).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis nonce check failed: %w", err)
+	}
+
+	return !ok, nil
+}
+
+// RedisDiscoveryCache 是 DiscoveryCache 的 Redis 实现，便于多实例共享发现结果
+type RedisDiscoveryCache struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisDiscoveryCache 创建一个基于 Redis 的 DiscoveryCache
+func NewRedisDiscoveryCache(client *redis.Client) *RedisDiscoveryCache {
+	return &RedisDiscoveryCache{client: client, prefix: "gg:openid:discovery:"}
+}
+
+// Get 实现 DiscoveryCache 接口
+func (c *RedisDiscoveryCache) Get(ctx context.Context, identifier string) (string, bool, error) {
+	val, err := c.client.Get(ctx, c.prefix+identifier).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("redis discovery lookup failed: %w", err)
+	}
+
+	return val, true, nil
+}
+
+// Set 实现 DiscoveryCache 接口
+func (c *RedisDiscoveryCache) Set(ctx context.Context, identifier, endpoint string) error {
+	if err := c.client.Set(ctx, c.prefix+identifier, endpoint, 0).Err(); err != nil {
+		return fmt.Errorf("redis discovery write failed: %w", err)
+	}
+	return nil
+}
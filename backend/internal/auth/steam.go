@@ -3,7 +3,6 @@ package auth
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"net/url"
@@ -17,7 +16,6 @@ import (
 )
 
 const steamAPIURL = "https://api.steampowered.com/ISteamUser/GetPlayerSummaries/v0002/"
-const steamVerifyURL = "https://steamcommunity.com/openid/login"
 
 var httpClient = &http.Client{
 	Timeout: 10 * time.Second,
@@ -37,47 +35,6 @@ func BuildSteamLoginURL(baseURL string) string {
 	return fmt.Sprintf("https://steamcommunity.com/openid/login?%s", params.Encode())
 }
 
-// VerifySteamResponse 验证Steam OpenID响应
-func VerifySteamResponse(queryParams map[string]string) (bool, error) {
-	// 检查模式
-	if queryParams["openid.mode"] != "id_res" {
-		return false, fmt.Errorf("invalid OpenID mode")
-	}
-
-	// 构建验证请求
-	verifyParams := url.Values{
-		"openid.assoc_handle": {queryParams["openid.assoc_handle"]},
-		"openid.signed":       {queryParams["openid.signed"]},
-		"openid.sig":          {queryParams["openid.sig"]},
-		"openid.ns":           {queryParams["openid.ns"]},
-		"openid.mode":         {"check_authentication"},
-	}
-
-	// 添加所有已签名的字段
-	signed := strings.Split(queryParams["openid.signed"], ",")
-	for _, field := range signed {
-		key := "openid." + field
-		if value, ok := queryParams[key]; ok {
-			verifyParams.Set(key, value)
-		}
-	}
-
-	// 向Steam验证
-	resp, err := httpClient.PostForm(steamVerifyURL, verifyParams)
-	if err != nil {
-		log.Printf("Steam verification error: %v", err)
-		return false, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return false, err
-	}
-
-	return strings.Contains(string(body), "is_valid:true"), nil
-}
-
 // ExtractSteamID 从OpenID响应中提取Steam ID
 func ExtractSteamID(claimedID string) (string, error) {
 	// 格式: https://steamcommunity.com/openid/id/[SteamID]
@@ -146,6 +103,42 @@ func GetSteamUserInfo(steamID string) (*models.SteamUser, error) {
 	}, nil
 }
 
+// ParseJWT 解析并验证JWT token，返回其中携带的用户信息
+func ParseJWT(tokenString string) (*models.TokenClaims, error) {
+	cfg := config.Get()
+
+	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return []byte(cfg.JWTSecret), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid or expired token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+
+	steamID, _ := claims["steamId"].(string)
+	if steamID == "" {
+		return nil, fmt.Errorf("token missing steamId claim")
+	}
+
+	username, _ := claims["username"].(string)
+	avatar, _ := claims["avatar"].(string)
+	profileURL, _ := claims["profileUrl"].(string)
+
+	return &models.TokenClaims{
+		SteamID:    steamID,
+		Username:   username,
+		Avatar:     avatar,
+		ProfileURL: profileURL,
+	}, nil
+}
+
 // GenerateJWT 生成JWT token
 func GenerateJWT(user *models.SteamUser) (string, error) {
 	cfg := config.Get()
@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"game-gallery-backend/internal/config"
+)
+
+const (
+	nonceExpiry            = 1 * time.Hour
+	steamDiscoveryIdentity = "https://steamcommunity.com/openid"
+	steamOPEndpoint        = "https://steamcommunity.com/openid/login"
+)
+
+// NonceStore 记录已经校验过的 (openid.response_nonce, endpoint) 组合，拒绝重复出现的回调
+type NonceStore interface {
+	// SeenBefore 在 nonce 此前已被记录过时返回 true；否则记录它并返回 false
+	SeenBefore(ctx context.Context, nonce, endpoint string) (bool, error)
+}
+
+// DiscoveryCache 缓存 OpenID Provider 的发现结果，避免每次回调都重新发现 endpoint
+type DiscoveryCache interface {
+	Get(ctx context.Context, identifier string) (endpoint string, ok bool, err error)
+	Set(ctx context.Context, identifier, endpoint string) error
+}
+
+// memoryNonceStore 是 NonceStore 的默认内存实现
+type memoryNonceStore struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// NewMemoryNonceStore 创建一个基于内存 map 的 NonceStore，后台定期清理过期条目
+func NewMemoryNonceStore() NonceStore {
+	store := &memoryNonceStore{seen: make(map[string]time.Time)}
+	go store.startCleanup()
+	return store
+}
+
+func (s *memoryNonceStore) key(nonce, endpoint string) string {
+	return endpoint + "|" + nonce
+}
+
+// SeenBefore 实现 NonceStore 接口
+func (s *memoryNonceStore) SeenBefore(_ context.Context, nonce, endpoint string) (bool, error) {
+	key := s.key(nonce, endpoint)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if seenAt, exists := s.seen[key]; exists && time.Since(seenAt) < nonceExpiry {
+		return true, nil
+	}
+
+	s.seen[key] = time.Now()
+	return false, nil
+}
+
+func (s *memoryNonceStore) startCleanup() {
+	ticker := time.NewTicker(nonceExpiry)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.mu.Lock()
+		now := time.Now()
+		for key, seenAt := range s.seen {
+			if now.Sub(seenAt) > nonceExpiry {
+				delete(s.seen, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// memoryDiscoveryCache 是 DiscoveryCache 的默认内存实现
+type memoryDiscoveryCache struct {
+	mu      sync.RWMutex
+	entries map[string]string
+}
+
+// NewMemoryDiscoveryCache 创建一个基于内存 map 的 DiscoveryCache
+func NewMemoryDiscoveryCache() DiscoveryCache {
+	return &memoryDiscoveryCache{entries: make(map[string]string)}
+}
+
+// Get 实现 DiscoveryCache 接口
+func (c *memoryDiscoveryCache) Get(_ context.Context, identifier string) (string, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	endpoint, ok := c.entries[identifier]
+	return endpoint, ok, nil
+}
+
+// Set 实现 DiscoveryCache 接口
+func (c *memoryDiscoveryCache) Set(_ context.Context, identifier, endpoint string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[identifier] = endpoint
+	return nil
+}
+
+// Verifier 验证 Steam OpenID 回调：拒绝重放的 nonce、校验 return_to/op_endpoint，并缓存发现结果
+type Verifier struct {
+	baseURL        string
+	nonceStore     NonceStore
+	discoveryCache DiscoveryCache
+}
+
+// NewVerifier 创建一个使用给定 NonceStore/DiscoveryCache 的 Verifier
+func NewVerifier(baseURL string, nonceStore NonceStore, discoveryCache DiscoveryCache) *Verifier {
+	return &Verifier{
+		baseURL:        baseURL,
+		nonceStore:     nonceStore,
+		discoveryCache: discoveryCache,
+	}
+}
+
+var (
+	defaultVerifier *Verifier
+	verifierOnce    sync.Once
+)
+
+// GetVerifier 获取默认 Verifier 单例；NonceStore/DiscoveryCache 的后端与 services.buildCache
+// 一样由 CACHE_BACKEND/REDIS_URL 决定，这样多实例部署时 nonce 防重放才能跨实例生效
+func GetVerifier() *Verifier {
+	verifierOnce.Do(func() {
+		cfg := config.Get()
+		nonceStore, discoveryCache := buildNonceAndDiscoveryStores(cfg)
+		defaultVerifier = NewVerifier(cfg.BaseURL, nonceStore, discoveryCache)
+	})
+	return defaultVerifier
+}
+
+// buildNonceAndDiscoveryStores 根据配置选择 NonceStore/DiscoveryCache 后端；Redis 配置无效时回退到内存实现
+func buildNonceAndDiscoveryStores(cfg *config.Config) (NonceStore, DiscoveryCache) {
+	if cfg.CacheBackend == "redis" && cfg.RedisURL != "" {
+		opts, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			log.Printf("Invalid REDIS_URL, falling back to in-memory nonce store and discovery cache: %v", err)
+			return NewMemoryNonceStore(), NewMemoryDiscoveryCache()
+		}
+
+		client := redis.NewClient(opts)
+		return NewRedisNonceStore(client), NewRedisDiscoveryCache(client)
+	}
+
+	return NewMemoryNonceStore(), NewMemoryDiscoveryCache()
+}
+
+// resolveOPEndpoint 返回 Steam OpenID Provider 的 endpoint，命中缓存则不重新发现
+func (v *Verifier) resolveOPEndpoint(ctx context.Context) (string, error) {
+	if endpoint, ok, err := v.discoveryCache.Get(ctx, steamDiscoveryIdentity); err != nil {
+		return "", fmt.Errorf("discovery cache lookup failed: %w", err)
+	} else if ok {
+		return endpoint, nil
+	}
+
+	// Steam 的 OpenID endpoint 是固定的，这里不做真正的 HTML discovery，只是把结果记下来
+	if err := v.discoveryCache.Set(ctx, steamDiscoveryIdentity, steamOPEndpoint); err != nil {
+		log.Printf("Failed to cache OpenID discovery result: %v", err)
+	}
+
+	return steamOPEndpoint, nil
+}
+
+// Verify 验证一次 Steam OpenID 回调
+func (v *Verifier) Verify(ctx context.Context, queryParams map[string]string) (bool, error) {
+	if queryParams["openid.mode"] != "id_res" {
+		return false, fmt.Errorf("invalid OpenID mode")
+	}
+
+	expectedReturnTo := v.baseURL + "/api/auth/steam/callback"
+	if queryParams["openid.return_to"] != expectedReturnTo {
+		return false, fmt.Errorf("return_to mismatch")
+	}
+
+	opEndpoint, err := v.resolveOPEndpoint(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if queryParams["openid.op_endpoint"] != opEndpoint {
+		return false, fmt.Errorf("unexpected op_endpoint: %s", queryParams["openid.op_endpoint"])
+	}
+
+	nonce := queryParams["openid.response_nonce"]
+	if nonce == "" {
+		return false, fmt.Errorf("missing response_nonce")
+	}
+
+	seen, err := v.nonceStore.SeenBefore(ctx, nonce, opEndpoint)
+	if err != nil {
+		return false, fmt.Errorf("failed to check nonce: %w", err)
+	}
+	if seen {
+		return false, fmt.Errorf("replayed response_nonce")
+	}
+
+	verifyParams := url.Values{
+		"openid.assoc_handle": {queryParams["openid.assoc_handle"]},
+		"openid.signed":       {queryParams["openid.signed"]},
+		"openid.sig":          {queryParams["openid.sig"]},
+		"openid.ns":           {queryParams["openid.ns"]},
+		"openid.mode":         {"check_authentication"},
+	}
+
+	signed := strings.Split(queryParams["openid.signed"], ",")
+	for _, field := range signed {
+		key := "openid." + field
+		if value, ok := queryParams[key]; ok {
+			verifyParams.Set(key, value)
+		}
+	}
+
+	resp, err := httpClient.PostForm(opEndpoint, verifyParams)
+	if err != nil {
+		log.Printf("Steam verification error: %v", err)
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+
+	return strings.Contains(string(body), "is_valid:true"), nil
+}
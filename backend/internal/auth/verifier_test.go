@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestMemoryNonceStore_SeenBefore_RejectsReplay(t *testing.T) {
+	store := NewMemoryNonceStore()
+	ctx := context.Background()
+
+	seen, err := store.SeenBefore(ctx, "nonce-1", "https://steamcommunity.com/openid/login")
+	if err != nil {
+		t.Fatalf("unexpected error on first call: %v", err)
+	}
+	if seen {
+		t.Fatalf("expected first occurrence of nonce to be unseen")
+	}
+
+	seen, err = store.SeenBefore(ctx, "nonce-1", "https://steamcommunity.com/openid/login")
+	if err != nil {
+		t.Fatalf("unexpected error on replay: %v", err)
+	}
+	if !seen {
+		t.Fatalf("expected replayed nonce to be rejected")
+	}
+}
+
+func TestMemoryNonceStore_SeenBefore_DistinguishesEndpoint(t *testing.T) {
+	store := NewMemoryNonceStore()
+	ctx := context.Background()
+
+	if seen, err := store.SeenBefore(ctx, "nonce-1", "https://steamcommunity.com/openid/login"); err != nil || seen {
+		t.Fatalf("expected first occurrence to be unseen, got seen=%v err=%v", seen, err)
+	}
+
+	seen, err := store.SeenBefore(ctx, "nonce-1", "https://other-endpoint.example/openid/login")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if seen {
+		t.Fatalf("same nonce on a different endpoint must not be treated as a replay")
+	}
+}
+
+func TestRedisNonceStore_SeenBefore_RejectsReplay(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	store := NewRedisNonceStore(client)
+	ctx := context.Background()
+
+	seen, err := store.SeenBefore(ctx, "nonce-1", "https://steamcommunity.com/openid/login")
+	if err != nil {
+		t.Fatalf("unexpected error on first call: %v", err)
+	}
+	if seen {
+		t.Fatalf("expected first occurrence of nonce to be unseen")
+	}
+
+	seen, err = store.SeenBefore(ctx, "nonce-1", "https://steamcommunity.com/openid/login")
+	if err != nil {
+		t.Fatalf("unexpected error on replay: %v", err)
+	}
+	if !seen {
+		t.Fatalf("expected replayed nonce to be rejected via Redis SETNX")
+	}
+}
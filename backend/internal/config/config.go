@@ -11,11 +11,15 @@ import (
 )
 
 type Config struct {
-	Port        int
-	JWTSecret   string
-	SteamAPIKey string
-	FrontendURL string
-	BaseURL     string
+	Port            int
+	JWTSecret       string
+	SteamAPIKey     string
+	FrontendURL     string
+	BaseURL         string
+	DataDir         string
+	CacheBackend    string
+	RedisURL        string
+	CacheMaxEntries int
 }
 
 var (
@@ -36,12 +40,23 @@ func Get() *Config {
 			}
 		}
 
+		maxEntries := 1000
+		if m := os.Getenv("CACHE_MAX_ENTRIES"); m != "" {
+			if parsed, err := strconv.Atoi(m); err == nil && parsed > 0 {
+				maxEntries = parsed
+			}
+		}
+
 		globalConfig = &Config{
-			Port:        port,
-			JWTSecret:   getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
-			SteamAPIKey: getEnv("STEAM_API_KEY", ""),
-			FrontendURL: getEnv("FRONTEND_URL", "http://localhost:5173"),
-			BaseURL:     getEnv("BASE_URL", "http://localhost:8080"),
+			Port:            port,
+			JWTSecret:       getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
+			SteamAPIKey:     getEnv("STEAM_API_KEY", ""),
+			FrontendURL:     getEnv("FRONTEND_URL", "http://localhost:5173"),
+			BaseURL:         getEnv("BASE_URL", "http://localhost:8080"),
+			DataDir:         getEnv("DATA_DIR", "./data"),
+			CacheBackend:    getEnv("CACHE_BACKEND", "memory"),
+			RedisURL:        getEnv("REDIS_URL", ""),
+			CacheMaxEntries: maxEntries,
 		}
 	})
 
@@ -17,12 +17,12 @@ type SteamAppListResponse struct {
 
 // GameSearchResult 表示游戏搜索结果
 type GameSearchResult struct {
-	ID               int    `json:"id"`
-	Name             string `json:"name"`
-	SteamURL         string `json:"steamUrl"`
-	CoverImage       string `json:"coverImage"`
-	CurrentPlayers   *int   `json:"currentPlayers,omitempty"`
-	Tags             []string `json:"tags"`
+	ID             int      `json:"id"`
+	Name           string   `json:"name"`
+	SteamURL       string   `json:"steamUrl"`
+	CoverImage     string   `json:"coverImage"`
+	CurrentPlayers *int     `json:"currentPlayers,omitempty"`
+	Tags           []string `json:"tags"`
 }
 
 // CurrentPlayersResponse 表示 GetNumberOfCurrentPlayers API 的响应
@@ -32,3 +32,68 @@ type CurrentPlayersResponse struct {
 		Result      int `json:"result"`
 	} `json:"response"`
 }
+
+// OwnedGame 表示用户 Steam 库中的一款游戏
+type OwnedGame struct {
+	AppID           int    `json:"appid"`
+	Name            string `json:"name"`
+	PlaytimeForever int    `json:"playtimeForever"`
+	PlaytimeRecent  int    `json:"playtimeRecent"`
+	IconURL         string `json:"iconUrl"`
+}
+
+// OwnedGamesResponse 表示 IPlayerService/GetOwnedGames API 的响应
+type OwnedGamesResponse struct {
+	Response struct {
+		GameCount int `json:"game_count"`
+		Games     []struct {
+			Appid           int    `json:"appid"`
+			Name            string `json:"name"`
+			PlaytimeForever int    `json:"playtime_forever"`
+			Playtime2Weeks  int    `json:"playtime_2weeks"`
+			ImgIconURL      string `json:"img_icon_url"`
+		} `json:"games"`
+	} `json:"response"`
+}
+
+// PriceOverview 表示 appdetails API 中的 price_overview 字段
+type PriceOverview struct {
+	Currency        string `json:"currency"`
+	Initial         int    `json:"initial"`
+	Final           int    `json:"final"`
+	DiscountPercent int    `json:"discountPercent"`
+}
+
+// AppDetailsResponse 表示 store.steampowered.com/api/appdetails 的响应
+// 该接口以 appid 为顶层 key，因此用 map 承接
+type AppDetailsResponse map[string]struct {
+	Success bool `json:"success"`
+	Data    struct {
+		PriceOverview struct {
+			Currency        string `json:"currency"`
+			Initial         int    `json:"initial"`
+			Final           int    `json:"final"`
+			DiscountPercent int    `json:"discount_percent"`
+		} `json:"price_overview"`
+	} `json:"data"`
+}
+
+// NewsItem 表示一条游戏新闻
+type NewsItem struct {
+	GID   string `json:"gid"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	Date  int64  `json:"date"`
+}
+
+// NewsForAppResponse 表示 ISteamNews/GetNewsForApp API 的响应
+type NewsForAppResponse struct {
+	Appnews struct {
+		Newsitems []struct {
+			Gid   string `json:"gid"`
+			Title string `json:"title"`
+			URL   string `json:"url"`
+			Date  int64  `json:"date"`
+		} `json:"newsitems"`
+	} `json:"appnews"`
+}
@@ -22,3 +22,56 @@ type SuccessResponse struct {
 	Data  any    `json:"data,omitempty"`
 	Token string `json:"token,omitempty"`
 }
+
+// Friend 表示好友列表中的一个条目，资料字段（用户名/头像等）通过 GetPlayerSummaries 批量补充
+type Friend struct {
+	SteamID      string `json:"steamId"`
+	Relationship string `json:"relationship"`
+	FriendSince  int64  `json:"friendSince"`
+	Username     string `json:"username,omitempty"`
+	Avatar       string `json:"avatar,omitempty"`
+	ProfileURL   string `json:"profileUrl,omitempty"`
+	PersonaState int    `json:"personaState,omitempty"`
+}
+
+// FriendListResponse 表示 ISteamUser/GetFriendList API 的响应
+type FriendListResponse struct {
+	Friendslist struct {
+		Friends []struct {
+			Steamid      string `json:"steamid"`
+			Relationship string `json:"relationship"`
+			FriendSince  int64  `json:"friend_since"`
+		} `json:"friends"`
+	} `json:"friendslist"`
+}
+
+// PlayerSummary 表示 Steam 用户的公开资料摘要
+type PlayerSummary struct {
+	SteamID      string `json:"steamId"`
+	Username     string `json:"username"`
+	Avatar       string `json:"avatar"`
+	ProfileURL   string `json:"profileUrl"`
+	PersonaState int    `json:"personaState"`
+}
+
+// PlayerSummariesResponse 表示 ISteamUser/GetPlayerSummaries API 的响应
+type PlayerSummariesResponse struct {
+	Response struct {
+		Players []struct {
+			Steamid      string `json:"steamid"`
+			Personaname  string `json:"personaname"`
+			Profileurl   string `json:"profileurl"`
+			Avatarfull   string `json:"avatarfull"`
+			Personastate int    `json:"personastate"`
+		} `json:"players"`
+	} `json:"response"`
+}
+
+// ResolveVanityURLResponse 表示 ISteamUser/ResolveVanityURL API 的响应
+type ResolveVanityURLResponse struct {
+	Response struct {
+		Steamid string `json:"steamid"`
+		Success int    `json:"success"`
+		Message string `json:"message"`
+	} `json:"response"`
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"expvar"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"game-gallery-backend/internal/config"
+)
+
+// Cache 是 Search/GetCurrentPlayers 等方法使用的缓存抽象，屏蔽内存与 Redis 后端的差异
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool)
+	Set(ctx context.Context, key string, val []byte, ttl time.Duration)
+	Delete(ctx context.Context, key string)
+	Stats() map[string]interface{}
+}
+
+// registerExpvarMap 注册（或复用已注册的）expvar.Map，避免同名多次 Publish 时 panic
+func registerExpvarMap(name string) *expvar.Map {
+	if v := expvar.Get(name); v != nil {
+		if m, ok := v.(*expvar.Map); ok {
+			return m
+		}
+	}
+
+	m := new(expvar.Map).Init()
+	expvar.Publish(name, m)
+	return m
+}
+
+// memoryCacheEntry 是内存缓存中的一个条目
+type memoryCacheEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// memoryCache 是 Cache 的内存实现，按最近最少使用（LRU）淘汰，容量受 maxEntries 限制
+type memoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+	stats      *expvar.Map
+}
+
+// NewMemoryCache 创建一个内存 Cache，maxEntries <= 0 表示不限制条目数
+func NewMemoryCache(name string, maxEntries int) Cache {
+	return &memoryCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		stats:      registerExpvarMap("gg_cache_" + name),
+	}
+}
+
+// Get 实现 Cache 接口
+func (c *memoryCache) Get(_ context.Context, key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		c.stats.Add("misses", 1)
+		return nil, false
+	}
+
+	entry := el.Value.(*memoryCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.ll.Remove(el)
+		delete(c.items, key)
+		c.stats.Add("misses", 1)
+		return nil, false
+	}
+
+	c.ll.MoveToFront(el)
+	c.stats.Add("hits", 1)
+	return entry.value, true
+}
+
+// Set 实现 Cache 接口，写入时按 LRU 淘汰超出 maxEntries 的最旧条目
+func (c *memoryCache) Set(_ context.Context, key string, val []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		entry := el.Value.(*memoryCacheEntry)
+		entry.value = val
+		entry.expiresAt = time.Now().Add(ttl)
+		return
+	}
+
+	el := c.ll.PushFront(&memoryCacheEntry{key: key, value: val, expiresAt: time.Now().Add(ttl)})
+	c.items[key] = el
+
+	if c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		oldest := c.ll.Back()
+		if oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*memoryCacheEntry).key)
+		}
+	}
+}
+
+// Delete 实现 Cache 接口
+func (c *memoryCache) Delete(_ context.Context, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.Remove(el)
+		delete(c.items, key)
+	}
+}
+
+// Stats 实现 Cache 接口
+func (c *memoryCache) Stats() map[string]interface{} {
+	c.mu.Lock()
+	entries := c.ll.Len()
+	c.mu.Unlock()
+
+	stats := map[string]interface{}{"backend": "memory", "entries": entries}
+	c.stats.Do(func(kv expvar.KeyValue) {
+		stats[kv.Key] = kv.Value.String()
+	})
+	return stats
+}
+
+// redisCache 是 Cache 的 Redis 实现，使得多个后端实例可以共享同一份缓存
+type redisCache struct {
+	client *redis.Client
+	stats  *expvar.Map
+}
+
+// NewRedisCache 创建一个基于 Redis 的 Cache
+func NewRedisCache(client *redis.Client, name string) Cache {
+	return &redisCache{
+		client: client,
+		stats:  registerExpvarMap("gg_cache_" + name),
+	}
+}
+
+// Get 实现 Cache 接口
+func (c *redisCache) Get(ctx context.Context, key string) ([]byte, bool) {
+	val, err := c.client.Get(ctx, key).Bytes()
+	if err != nil {
+		c.stats.Add("misses", 1)
+		return nil, false
+	}
+
+	c.stats.Add("hits", 1)
+	return val, true
+}
+
+// Set 实现 Cache 接口
+func (c *redisCache) Set(ctx context.Context, key string, val []byte, ttl time.Duration) {
+	if err := c.client.Set(ctx, key, val, ttl).Err(); err != nil {
+		log.Printf("redis cache: failed to set key %s: %v", key, err)
+	}
+}
+
+// Delete 实现 Cache 接口
+func (c *redisCache) Delete(ctx context.Context, key string) {
+	if err := c.client.Del(ctx, key).Err(); err != nil {
+		log.Printf("redis cache: failed to delete key %s: %v", key, err)
+	}
+}
+
+// Stats 实现 Cache 接口
+func (c *redisCache) Stats() map[string]interface{} {
+	stats := map[string]interface{}{"backend": "redis"}
+	c.stats.Do(func(kv expvar.KeyValue) {
+		stats[kv.Key] = kv.Value.String()
+	})
+	return stats
+}
+
+// buildCache 根据配置选择缓存后端；Redis 配置无效时回退到内存实现
+func buildCache(name string) Cache {
+	cfg := config.Get()
+
+	if cfg.CacheBackend == "redis" && cfg.RedisURL != "" {
+		opts, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			log.Printf("Invalid REDIS_URL, falling back to in-memory cache: %v", err)
+			return NewMemoryCache(name, cfg.CacheMaxEntries)
+		}
+		return NewRedisCache(redis.NewClient(opts), name)
+	}
+
+	return NewMemoryCache(name, cfg.CacheMaxEntries)
+}
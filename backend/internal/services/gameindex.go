@@ -0,0 +1,419 @@
+package services
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"game-gallery-backend/internal/config"
+	"game-gallery-backend/internal/models"
+)
+
+const (
+	appListRefreshInterval    = 24 * time.Hour   // 全量 App 列表的刷新周期
+	indexPopularityDuration   = 10 * time.Minute // 人气代理指标（当前在线人数）的缓存时长
+	fuzzyMaxDistance          = 2                // 模糊匹配允许的最大编辑距离
+	fuzzyMinQueryLen          = 3                // 短于该长度的查询不做模糊匹配，避免误匹配过多
+	popularityWarmConcurrency = 3                // 后台异步预热人气指标时，同时在途的采样请求数上限
+)
+
+// indexedApp 是索引中的一个条目，额外保存小写名称以加速匹配
+type indexedApp struct {
+	app       models.SteamApp
+	lowerName string
+}
+
+// popularityEntry 是人气代理指标的缓存条目
+type popularityEntry struct {
+	count     int
+	timestamp time.Time
+}
+
+// GameIndex 维护 Steam 全量 App 列表的本地索引，支持子串、前缀与模糊匹配，
+// 并按人气代理指标（当前在线人数，惰性采样）对结果排序
+type GameIndex struct {
+	mu      sync.RWMutex
+	entries []indexedApp
+
+	popMu      sync.Mutex
+	popularity map[int]*popularityEntry
+
+	steamService *SteamService
+	dataPath     string
+}
+
+var (
+	gameIndexInstance *GameIndex
+	gameIndexOnce     sync.Once
+)
+
+// GetGameIndex 获取 GameIndex 单例，首次调用时尝试从磁盘加载已持久化的列表
+func GetGameIndex() *GameIndex {
+	gameIndexOnce.Do(func() {
+		cfg := config.Get()
+		gameIndexInstance = &GameIndex{
+			popularity:   make(map[int]*popularityEntry),
+			steamService: GetSteamService(),
+			dataPath:     filepath.Join(cfg.DataDir, "applist.json.gz"),
+		}
+		gameIndexInstance.loadFromDisk()
+	})
+	return gameIndexInstance
+}
+
+// StartGameIndexRefresh 组装默认的 GameIndex，必要时做一次同步的冷启动刷新，并启动每日刷新协程
+func StartGameIndexRefresh() (*GameIndex, error) {
+	idx := GetGameIndex()
+
+	if !idx.Ready() {
+		if err := idx.Refresh(); err != nil {
+			log.Printf("Warning: initial game index refresh failed, search will fall back to Steam Store API: %v", err)
+		}
+	}
+
+	go idx.runDailyRefresh()
+
+	return idx, nil
+}
+
+// Ready 返回索引是否已经有可用数据
+func (gi *GameIndex) Ready() bool {
+	gi.mu.RLock()
+	defer gi.mu.RUnlock()
+	return len(gi.entries) > 0
+}
+
+// Refresh 拉取最新的全量 App 列表，持久化到磁盘后原子地替换内存索引
+func (gi *GameIndex) Refresh() error {
+	apps, err := gi.steamService.GetAppList()
+	if err != nil {
+		return fmt.Errorf("failed to refresh app list: %w", err)
+	}
+
+	if err := gi.persistToDisk(apps); err != nil {
+		log.Printf("Warning: failed to persist app list cache: %v", err)
+	}
+
+	gi.setApps(apps)
+	log.Printf("Refreshed game index with %d apps", len(apps))
+	return nil
+}
+
+// runDailyRefresh 每天重新拉取一次全量 App 列表
+func (gi *GameIndex) runDailyRefresh() {
+	ticker := time.NewTicker(appListRefreshInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := gi.Refresh(); err != nil {
+			log.Printf("Warning: daily game index refresh failed: %v", err)
+		}
+	}
+}
+
+// setApps 原子地替换内存中的索引条目
+func (gi *GameIndex) setApps(apps []models.SteamApp) {
+	entries := make([]indexedApp, 0, len(apps))
+	for _, app := range apps {
+		entries = append(entries, indexedApp{app: app, lowerName: strings.ToLower(app.Name)})
+	}
+
+	gi.mu.Lock()
+	gi.entries = entries
+	gi.mu.Unlock()
+}
+
+// loadFromDisk 尝试从 $DATA_DIR/applist.json.gz 加载上次持久化的列表，文件不存在时静默跳过
+func (gi *GameIndex) loadFromDisk() {
+	f, err := os.Open(gi.dataPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Warning: failed to open app list cache %s: %v", gi.dataPath, err)
+		}
+		return
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		log.Printf("Warning: failed to read app list cache %s: %v", gi.dataPath, err)
+		return
+	}
+	defer gz.Close()
+
+	var apps []models.SteamApp
+	if err := json.NewDecoder(gz).Decode(&apps); err != nil {
+		log.Printf("Warning: failed to decode app list cache %s: %v", gi.dataPath, err)
+		return
+	}
+
+	gi.setApps(apps)
+	log.Printf("Loaded %d apps from local index cache", len(apps))
+}
+
+// persistToDisk 将 App 列表以 gzip JSON 的形式原子写入 $DATA_DIR/applist.json.gz
+func (gi *GameIndex) persistToDisk(apps []models.SteamApp) error {
+	if err := os.MkdirAll(filepath.Dir(gi.dataPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create data dir: %w", err)
+	}
+
+	tmpPath := gi.dataPath + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	gz := gzip.NewWriter(f)
+	if err := json.NewEncoder(gz).Encode(apps); err != nil {
+		gz.Close()
+		f.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to encode app list: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to flush gzip writer: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, gi.dataPath); err != nil {
+		return fmt.Errorf("failed to replace app list cache: %w", err)
+	}
+
+	return nil
+}
+
+// matchCandidate 是一个搜索候选项，rank 越小表示匹配质量越高：0=前缀匹配，1=子串匹配，2+distance=模糊匹配
+type matchCandidate struct {
+	app  models.SteamApp
+	rank int
+}
+
+// Search 在本地索引中查找游戏：先做子串/前缀匹配，不足 limit 个时补充模糊匹配，
+// 最终在候选池内按人气代理指标（当前在线人数）排序后截取前 limit 个
+func (gi *GameIndex) Search(query string, limit int) []models.SteamApp {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+
+	gi.mu.RLock()
+	entries := gi.entries
+	gi.mu.RUnlock()
+
+	seen := make(map[int]bool)
+	var candidates []matchCandidate
+
+	for _, e := range entries {
+		switch {
+		case strings.HasPrefix(e.lowerName, query):
+			candidates = append(candidates, matchCandidate{e.app, 0})
+			seen[e.app.AppID] = true
+		case strings.Contains(e.lowerName, query):
+			candidates = append(candidates, matchCandidate{e.app, 1})
+			seen[e.app.AppID] = true
+		}
+	}
+
+	if len(candidates) < limit && len([]rune(query)) >= fuzzyMinQueryLen {
+		queryRunes := []rune(query)
+		maxCandidates := limit * 5
+
+		for _, e := range entries {
+			if seen[e.app.AppID] || len(candidates) >= maxCandidates {
+				continue
+			}
+
+			if dist, ok := bestTokenDistance(queryRunes, e.lowerName); ok {
+				candidates = append(candidates, matchCandidate{e.app, 2 + dist})
+				seen[e.app.AppID] = true
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].rank < candidates[j].rank
+	})
+
+	poolSize := limit * 3
+	if poolSize > len(candidates) {
+		poolSize = len(candidates)
+	}
+	pool := candidates[:poolSize]
+
+	// 只用已缓存的人气指标排序，未采样过的 app 按 0 分处理（稳定排序下仍保留原有的匹配质量顺序），
+	// 避免在请求路径上同步调用 Steam API
+	sort.SliceStable(pool, func(i, j int) bool {
+		return gi.cachedPopularityScore(pool[i].app.AppID) > gi.cachedPopularityScore(pool[j].app.AppID)
+	})
+
+	if len(pool) > limit {
+		pool = pool[:limit]
+	}
+
+	gi.warmPopularity(pool)
+
+	results := make([]models.SteamApp, 0, len(pool))
+	for _, c := range pool {
+		results = append(results, c.app)
+	}
+	return results
+}
+
+// Suggest 仅做前缀匹配，不采样人气指标，供 /api/games/suggest 的输入提示使用
+func (gi *GameIndex) Suggest(prefix string, limit int) []models.SteamApp {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" {
+		return nil
+	}
+
+	gi.mu.RLock()
+	entries := gi.entries
+	gi.mu.RUnlock()
+
+	results := make([]models.SteamApp, 0, limit)
+	for _, e := range entries {
+		if strings.HasPrefix(e.lowerName, prefix) {
+			results = append(results, e.app)
+			if len(results) >= limit {
+				break
+			}
+		}
+	}
+
+	return results
+}
+
+// cachedPopularityScore 返回 appid 已缓存的人气代理指标（当前在线人数），没有新鲜缓存时返回 0，
+// 不会在调用方所在的（通常是 HTTP 请求处理）goroutine 里同步拉取 Steam API
+func (gi *GameIndex) cachedPopularityScore(appID int) int {
+	gi.popMu.Lock()
+	defer gi.popMu.Unlock()
+
+	entry, ok := gi.popularity[appID]
+	if !ok || time.Since(entry.timestamp) >= indexPopularityDuration {
+		return 0
+	}
+	return entry.count
+}
+
+// warmPopularity 异步、有限并发地为 candidates 中尚未缓存人气指标的 app 采样一次，
+// 供后续搜索命中缓存；不会阻塞调用方，同时在途的采样请求不超过 popularityWarmConcurrency 个
+func (gi *GameIndex) warmPopularity(candidates []matchCandidate) {
+	sem := make(chan struct{}, popularityWarmConcurrency)
+
+	for _, c := range candidates {
+		appID := c.app.AppID
+
+		gi.popMu.Lock()
+		entry, ok := gi.popularity[appID]
+		gi.popMu.Unlock()
+		if ok && time.Since(entry.timestamp) < indexPopularityDuration {
+			continue
+		}
+
+		go func(appID int) {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			count, err := gi.steamService.GetCurrentPlayers(appID)
+			if err != nil {
+				return
+			}
+
+			gi.popMu.Lock()
+			gi.popularity[appID] = &popularityEntry{count: count, timestamp: time.Now()}
+			gi.popMu.Unlock()
+		}(appID)
+	}
+}
+
+// bestTokenDistance 在 name 的空白分词中寻找与 query 编辑距离最小的一个，
+// 若最小距离在 fuzzyMaxDistance 以内则返回该距离
+func bestTokenDistance(query []rune, name string) (int, bool) {
+	best := -1
+
+	for _, token := range strings.Fields(name) {
+		tokenRunes := []rune(token)
+		if abs(len(tokenRunes)-len(query)) > fuzzyMaxDistance {
+			continue
+		}
+
+		dist := damerauLevenshtein(query, tokenRunes)
+		if dist <= fuzzyMaxDistance && (best == -1 || dist < best) {
+			best = dist
+		}
+	}
+
+	if best == -1 {
+		return 0, false
+	}
+	return best, true
+}
+
+// damerauLevenshtein 计算两个 rune 序列之间的（受限版）Damerau-Levenshtein 编辑距离
+func damerauLevenshtein(a, b []rune) int {
+	la, lb := len(a), len(b)
+
+	d := make([][]int, la+1)
+	for i := range d {
+		d[i] = make([]int, lb+1)
+		d[i][0] = i
+	}
+	for j := 0; j <= lb; j++ {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= la; i++ {
+		for j := 1; j <= lb; j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+cost)
+			}
+		}
+	}
+
+	return d[la][lb]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"game-gallery-backend/internal/store"
+)
+
+// Notifier 是订阅通知的投递方式，Scheduler 对每条触发的订阅都会调用全部已注册的 Notifier
+type Notifier interface {
+	Notify(sub *store.Subscription, message string) error
+}
+
+// WebhookNotifier 将通知以 POST 请求投递到订阅上配置的 webhook URL
+type WebhookNotifier struct {
+	httpClient *http.Client
+}
+
+// NewWebhookNotifier 创建一个 WebhookNotifier。httpClient 使用自定义 DialContext，
+// 每次投递都重新解析 webhook 主机名并把连接钉死在校验通过的那个 IP 上，防止订阅创建之后
+// 通过 DNS rebinding 把后续每次 tick 的请求改道到回环/内网/链路本地地址（SSRF）
+func NewWebhookNotifier() *WebhookNotifier {
+	return &WebhookNotifier{
+		httpClient: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{DialContext: dialPinnedPublicIP},
+		},
+	}
+}
+
+// dialPinnedPublicIP 解析 addr 的主机名，过滤掉回环/内网/链路本地/未指定地址后，
+// 直接拨号到剩余的某个公网 IP（而不是把原始主机名交给 net/http 再解析一次）
+func dialPinnedPublicIP(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve host %q: %w", host, err)
+	}
+
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+
+	var lastErr error
+	for _, ipAddr := range ips {
+		if isDisallowedWebhookIP(ipAddr.IP) {
+			lastErr = fmt.Errorf("refusing to dial loopback/private/link-local address %s", ipAddr.IP)
+			continue
+		}
+
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ipAddr.IP.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no usable address found for host %q", host)
+	}
+	return nil, lastErr
+}
+
+// isDisallowedWebhookIP 判断 ip 是否为回环/内网/链路本地/未指定地址
+func isDisallowedWebhookIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+// Notify 实现 Notifier 接口，没有配置 webhook 的订阅会被静默跳过
+func (n *WebhookNotifier) Notify(sub *store.Subscription, message string) error {
+	if sub.WebhookURL == "" {
+		return nil
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"subscriptionId": sub.ID,
+		"appid":          sub.AppID,
+		"message":        message,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	resp, err := n.httpClient.Post(sub.WebhookURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to deliver webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("webhook endpoint returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// SSENotifier 将通知推送给通过 /api/subscriptions/stream 连接的客户端
+type SSENotifier struct {
+	mu      sync.RWMutex
+	clients map[string][]chan string
+}
+
+// NewSSENotifier 创建一个 SSENotifier
+func NewSSENotifier() *SSENotifier {
+	return &SSENotifier{
+		clients: make(map[string][]chan string),
+	}
+}
+
+// Notify 实现 Notifier 接口，向该用户当前所有打开的 SSE 连接广播消息
+func (n *SSENotifier) Notify(sub *store.Subscription, message string) error {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	for _, ch := range n.clients[sub.SteamID] {
+		select {
+		case ch <- message:
+		default:
+			// 客户端消费过慢，丢弃这条消息而不是阻塞调度器
+		}
+	}
+
+	return nil
+}
+
+// Subscribe 注册一个新的 SSE 连接，返回的 channel 会收到该用户的后续通知
+func (n *SSENotifier) Subscribe(steamID string) chan string {
+	ch := make(chan string, 8)
+
+	n.mu.Lock()
+	n.clients[steamID] = append(n.clients[steamID], ch)
+	n.mu.Unlock()
+
+	return ch
+}
+
+// Unsubscribe 注销一个 SSE 连接并关闭其 channel
+func (n *SSENotifier) Unsubscribe(steamID string, ch chan string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	chans := n.clients[steamID]
+	for i, c := range chans {
+		if c == ch {
+			n.clients[steamID] = append(chans[:i], chans[i+1:]...)
+			close(ch)
+			break
+		}
+	}
+}
+
+var (
+	sseInstance *SSENotifier
+	sseOnce     sync.Once
+)
+
+// GetSSENotifier 获取 SSENotifier 单例，供 Scheduler 和 SSE handler 共用
+func GetSSENotifier() *SSENotifier {
+	sseOnce.Do(func() {
+		sseInstance = NewSSENotifier()
+	})
+	return sseInstance
+}
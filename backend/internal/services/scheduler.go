@@ -0,0 +1,149 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"game-gallery-backend/internal/store"
+)
+
+const schedulerInterval = 1 * time.Minute
+
+// Scheduler 定期巡检订阅，发现玩家数越过阈值、打折或有新闻时触发通知
+type Scheduler struct {
+	steamService *SteamService
+	subStore     *store.SubscriptionStore
+	notifiers    []Notifier
+	rateLimiter  *tokenBucket // 只节流 Scheduler 自己对 Steam API 的调用，不影响搜索等请求路径
+}
+
+// NewScheduler 创建一个 Scheduler
+func NewScheduler(steamService *SteamService, subStore *store.SubscriptionStore, notifiers ...Notifier) *Scheduler {
+	return &Scheduler{
+		steamService: steamService,
+		subStore:     subStore,
+		notifiers:    notifiers,
+		rateLimiter:  newTokenBucket(steamRateLimit, 1),
+	}
+}
+
+// StartScheduler 组装默认的 Scheduler（SteamService + SubscriptionStore + Webhook/SSE 通知）并启动它
+func StartScheduler() (*Scheduler, error) {
+	subStore, err := store.GetSubscriptionStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start scheduler: %w", err)
+	}
+
+	scheduler := NewScheduler(GetSteamService(), subStore, NewWebhookNotifier(), GetSSENotifier())
+	scheduler.Start()
+
+	return scheduler, nil
+}
+
+// Start 启动后台巡检循环，每分钟执行一次
+func (s *Scheduler) Start() {
+	go s.run()
+}
+
+func (s *Scheduler) run() {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	s.tick()
+	for range ticker.C {
+		s.tick()
+	}
+}
+
+// tick 按 appid 聚合订阅，避免对同一款游戏重复调用 Steam API
+func (s *Scheduler) tick() {
+	subs, err := s.subStore.All()
+	if err != nil {
+		log.Printf("Scheduler: failed to load subscriptions: %v", err)
+		return
+	}
+
+	byApp := make(map[int][]*store.Subscription)
+	for _, sub := range subs {
+		byApp[sub.AppID] = append(byApp[sub.AppID], sub)
+	}
+
+	for appID, appSubs := range byApp {
+		s.rateLimiter.wait()
+		count, err := s.steamService.GetCurrentPlayers(appID)
+		if err != nil {
+			log.Printf("Scheduler: failed to get player count for app %d: %v", appID, err)
+			continue
+		}
+
+		wantSale, wantNews := false, false
+		for _, sub := range appSubs {
+			wantSale = wantSale || sub.NotifyOnSale
+			wantNews = wantNews || sub.NotifyOnNews
+		}
+
+		discount := 0
+		if wantSale {
+			s.rateLimiter.wait()
+			if overview, err := s.steamService.GetPriceOverview(appID); err != nil {
+				log.Printf("Scheduler: failed to get price overview for app %d: %v", appID, err)
+			} else if overview != nil {
+				discount = overview.DiscountPercent
+			}
+		}
+
+		newsGID := ""
+		if wantNews {
+			s.rateLimiter.wait()
+			if item, err := s.steamService.GetLatestNews(appID); err != nil {
+				log.Printf("Scheduler: failed to get news for app %d: %v", appID, err)
+			} else if item != nil {
+				newsGID = item.GID
+			}
+		}
+
+		for _, sub := range appSubs {
+			s.evaluate(sub, count, discount, newsGID)
+		}
+	}
+}
+
+// evaluate 比较这一轮的观测值与订阅上次记录的值，触发通知并持久化新的观测值
+func (s *Scheduler) evaluate(sub *store.Subscription, count, discount int, newsGID string) {
+	var messages []string
+
+	if sub.Threshold > 0 && sub.LastPlayerCount < sub.Threshold && count >= sub.Threshold {
+		messages = append(messages, fmt.Sprintf("App %d crossed %d current players (now %d)", sub.AppID, sub.Threshold, count))
+	}
+
+	if sub.NotifyOnSale && discount > 0 && sub.LastDiscountPercent == 0 {
+		messages = append(messages, fmt.Sprintf("App %d is now on sale (%d%% off)", sub.AppID, discount))
+	}
+
+	if sub.NotifyOnNews && newsGID != "" && sub.LastNewsGID != "" && newsGID != sub.LastNewsGID {
+		messages = append(messages, fmt.Sprintf("New news posted for app %d", sub.AppID))
+	}
+
+	for _, msg := range messages {
+		s.notify(sub, msg)
+	}
+
+	sub.LastPlayerCount = count
+	sub.LastDiscountPercent = discount
+	if newsGID != "" {
+		sub.LastNewsGID = newsGID
+	}
+
+	if err := s.subStore.Update(sub); err != nil {
+		log.Printf("Scheduler: failed to persist subscription %s: %v", sub.ID, err)
+	}
+}
+
+func (s *Scheduler) notify(sub *store.Subscription, message string) {
+	for _, n := range s.notifiers {
+		if err := n.Notify(sub, message); err != nil {
+			log.Printf("Scheduler: notifier failed for subscription %s: %v", sub.ID, err)
+		}
+	}
+}
@@ -1,37 +1,82 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"game-gallery-backend/internal/config"
 	"game-gallery-backend/internal/models"
 	"golang.org/x/net/proxy"
 )
 
 const (
-	steamSearchAPIURL      = "https://store.steampowered.com/api/storesearch/"
-	steamCurrentPlayersURL = "https://api.steampowered.com/ISteamUserStats/GetNumberOfCurrentPlayers/v1/"
-	searchCacheDuration    = 10 * time.Minute // 搜索结果缓存 10 分钟
+	steamSearchAPIURL        = "https://store.steampowered.com/api/storesearch/"
+	steamCurrentPlayersURL   = "https://api.steampowered.com/ISteamUserStats/GetNumberOfCurrentPlayers/v1/"
+	steamResolveVanityURL    = "https://api.steampowered.com/ISteamUser/ResolveVanityURL/v1/"
+	steamFriendListURL       = "https://api.steampowered.com/ISteamUser/GetFriendList/v1/"
+	steamPlayerSummariesURL  = "https://api.steampowered.com/ISteamUser/GetPlayerSummaries/v2/"
+	steamOwnedGamesURL       = "https://api.steampowered.com/IPlayerService/GetOwnedGames/v1/"
+	steamAppDetailsURL       = "https://store.steampowered.com/api/appdetails/"
+	steamNewsForAppURL       = "https://api.steampowered.com/ISteamNews/GetNewsForApp/v2/"
+	steamAppListURL          = "https://api.steampowered.com/IStoreService/GetAppList/v1/"
+	steamPlayerSummariesPage = 100   // GetPlayerSummaries 单次请求最多支持的 steamid 数量
+	appListPageMaxResults    = 50000 // GetAppList 单页最多请求的数量
+
+	searchCacheDuration         = 10 * time.Minute // 搜索结果缓存 10 分钟
+	currentPlayersCacheDuration = 60 * time.Second // 当前在线人数缓存 60 秒
+	friendsCacheDuration        = 1 * time.Hour    // 好友列表缓存 1 小时
+	summariesCacheDuration      = 5 * time.Minute  // 玩家资料缓存 5 分钟
+	libraryCacheDuration        = 1 * time.Hour    // 游戏库缓存 1 小时
+
+	steamRateLimit = 1 * time.Second // 调用 Steam API 的最小间隔，避免触发限流
 )
 
-// searchCacheEntry 搜索结果缓存条目
-type searchCacheEntry struct {
-	results   []models.SteamApp
-	timestamp time.Time
+// tokenBucket 是一个简单的令牌桶限流器
+type tokenBucket struct {
+	tokens chan struct{}
 }
 
-// SteamService 管理 Steam API 调用和缓存
+// newTokenBucket 创建一个令牌桶，每隔 interval 补充一个令牌，容量为 burst
+func newTokenBucket(interval time.Duration, burst int) *tokenBucket {
+	tb := &tokenBucket{tokens: make(chan struct{}, burst)}
+	for i := 0; i < burst; i++ {
+		tb.tokens <- struct{}{}
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			select {
+			case tb.tokens <- struct{}{}:
+			default:
+				// 桶已满，丢弃这次补充
+			}
+		}
+	}()
+
+	return tb
+}
+
+// wait 阻塞直到获取到一个令牌
+func (tb *tokenBucket) wait() {
+	<-tb.tokens
+}
+
+// SteamService 管理 Steam API 调用和缓存。所有结果缓存（搜索、在线人数、好友、资料、游戏库）
+// 都经由同一个可插拔的 Cache 实现（内存或 Redis），各自用不同的 key 前缀区分
 type SteamService struct {
-	searchCache map[string]*searchCacheEntry
-	mu          sync.RWMutex
-	httpClient  *http.Client
+	cache      Cache
+	httpClient *http.Client
 }
 
 var (
@@ -43,12 +88,10 @@ var (
 func GetSteamService() *SteamService {
 	once.Do(func() {
 		instance = &SteamService{
-			searchCache: make(map[string]*searchCacheEntry),
-			httpClient:  createHTTPClient(),
+			cache:      buildCache("steam"),
+			httpClient: createHTTPClient(),
 		}
-		log.Println("Steam service initialized with search cache")
-		// 启动定期清理过期缓存
-		go instance.startCacheCleanup()
+		log.Printf("Steam service initialized with %s cache backend", config.Get().CacheBackend)
 	})
 	return instance
 }
@@ -92,11 +135,18 @@ func createHTTPClient() *http.Client {
 	}
 }
 
+// get 对 Steam API 发起 GET 请求。不在这里做全局限流：SteamService 的方法同时被并发的
+// 搜索/资料查询和串行的 Scheduler 巡检共用，对所有调用方限流会把搜索接口原本并发的
+// per-app 查询也拖成串行。需要限流的调用方（目前是 Scheduler）自带节流，见 scheduler.go
+func (s *SteamService) get(url string) (*http.Response, error) {
+	return s.httpClient.Get(url)
+}
+
 // searchSteamStore 直接搜索 Steam Store API（后端代理，避免 CORS）
 func (s *SteamService) searchSteamStore(query string, limit int) ([]models.SteamApp, error) {
 	url := fmt.Sprintf("%s?term=%s&l=schinese&cc=CN", steamSearchAPIURL, query)
 
-	resp, err := s.httpClient.Get(url)
+	resp, err := s.get(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search Steam store: %w", err)
 	}
@@ -133,21 +183,15 @@ func (s *SteamService) searchSteamStore(query string, limit int) ([]models.Steam
 	return apps, nil
 }
 
-// startCacheCleanup 定期清理过期的搜索缓存
-func (s *SteamService) startCacheCleanup() {
-	ticker := time.NewTicker(5 * time.Minute)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		s.mu.Lock()
-		now := time.Now()
-		for key, entry := range s.searchCache {
-			if now.Sub(entry.timestamp) > searchCacheDuration {
-				delete(s.searchCache, key)
-			}
+// searchGames 优先使用本地游戏索引搜索，索引尚未就绪（冷启动）时回退到 Steam Store API
+func (s *SteamService) searchGames(query string, limit int) ([]models.SteamApp, error) {
+	if idx := GetGameIndex(); idx.Ready() {
+		if apps := idx.Search(query, limit); len(apps) > 0 {
+			return apps, nil
 		}
-		s.mu.Unlock()
 	}
+
+	return s.searchSteamStore(query, limit)
 }
 
 // Search 搜索游戏（带缓存）
@@ -157,42 +201,46 @@ func (s *SteamService) Search(query string, limit int) ([]models.SteamApp, error
 	}
 
 	query = strings.TrimSpace(query)
-	cacheKey := fmt.Sprintf("%s:%d", query, limit)
+	ctx := context.Background()
+	cacheKey := fmt.Sprintf("gg:search:%s:%d", query, limit)
 
-	// 检查缓存
-	s.mu.RLock()
-	if cached, exists := s.searchCache[cacheKey]; exists {
-		if time.Since(cached.timestamp) < searchCacheDuration {
-			s.mu.RUnlock()
+	if cached, ok := s.cache.Get(ctx, cacheKey); ok {
+		var apps []models.SteamApp
+		if err := json.Unmarshal(cached, &apps); err == nil {
 			log.Printf("Cache hit for query: %s", query)
-			return cached.results, nil
+			return apps, nil
 		}
 	}
-	s.mu.RUnlock()
 
-	// 缓存未命中或已过期，调用 Steam Store API
-	log.Printf("Cache miss for query: %s, fetching from Steam...", query)
-	apps, err := s.searchSteamStore(query, limit)
+	// 缓存未命中或已过期，优先查询本地索引，索引未就绪时回退到 Steam Store API
+	log.Printf("Cache miss for query: %s, searching...", query)
+	apps, err := s.searchGames(query, limit)
 	if err != nil {
 		return nil, err
 	}
 
-	// 更新缓存
-	s.mu.Lock()
-	s.searchCache[cacheKey] = &searchCacheEntry{
-		results:   apps,
-		timestamp: time.Now(),
+	if encoded, err := json.Marshal(apps); err == nil {
+		s.cache.Set(ctx, cacheKey, encoded, searchCacheDuration)
 	}
-	s.mu.Unlock()
 
 	return apps, nil
 }
 
-// GetCurrentPlayers 获取游戏的当前在线玩家数
+// GetCurrentPlayers 获取游戏的当前在线玩家数（带缓存）
 func (s *SteamService) GetCurrentPlayers(appID int) (int, error) {
+	ctx := context.Background()
+	cacheKey := fmt.Sprintf("gg:players:%d", appID)
+
+	if cached, ok := s.cache.Get(ctx, cacheKey); ok {
+		var count int
+		if err := json.Unmarshal(cached, &count); err == nil {
+			return count, nil
+		}
+	}
+
 	url := fmt.Sprintf("%s?appid=%d", steamCurrentPlayersURL, appID)
 
-	resp, err := s.httpClient.Get(url)
+	resp, err := s.get(url)
 	if err != nil {
 		return 0, fmt.Errorf("failed to fetch current players: %w", err)
 	}
@@ -211,16 +259,369 @@ func (s *SteamService) GetCurrentPlayers(appID int) (int, error) {
 		return 0, fmt.Errorf("steam API returned result code %d", data.Response.Result)
 	}
 
+	if encoded, err := json.Marshal(data.Response.PlayerCount); err == nil {
+		s.cache.Set(ctx, cacheKey, encoded, currentPlayersCacheDuration)
+	}
+
 	return data.Response.PlayerCount, nil
 }
 
-// GetCacheStats 获取缓存统计信息
+// GetCacheStats 获取缓存统计信息。所有结果缓存共用同一个 Cache 实例，
+// 因此这里的命中/未命中统计同时覆盖搜索、在线人数、好友、资料与游戏库
 func (s *SteamService) GetCacheStats() map[string]interface{} {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
 	return map[string]interface{}{
-		"cached_searches": len(s.searchCache),
-		"cache_duration":  searchCacheDuration.String(),
+		"cache":          s.cache.Stats(),
+		"cache_duration": searchCacheDuration.String(),
 	}
 }
+
+// ResolveVanity 将 Steam 自定义 URL（vanity name）解析为 64 位 SteamID
+func (s *SteamService) ResolveVanity(vanityName string) (string, error) {
+	cfg := config.Get()
+	if cfg.SteamAPIKey == "" {
+		return "", fmt.Errorf("STEAM_API_KEY not configured")
+	}
+
+	params := url.Values{
+		"key":       {cfg.SteamAPIKey},
+		"vanityurl": {vanityName},
+	}
+
+	reqURL := fmt.Sprintf("%s?%s", steamResolveVanityURL, params.Encode())
+	resp, err := s.get(reqURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve vanity URL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("steam API returned status %d", resp.StatusCode)
+	}
+
+	var data models.ResolveVanityURLResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if data.Response.Success != 1 {
+		return "", fmt.Errorf("could not resolve vanity URL: %s", data.Response.Message)
+	}
+
+	return data.Response.Steamid, nil
+}
+
+// GetFriends 获取指定用户的好友列表（带缓存）
+func (s *SteamService) GetFriends(steamID string) ([]models.Friend, error) {
+	ctx := context.Background()
+	cacheKey := fmt.Sprintf("gg:friends:%s", steamID)
+
+	if cached, ok := s.cache.Get(ctx, cacheKey); ok {
+		var friends []models.Friend
+		if err := json.Unmarshal(cached, &friends); err == nil {
+			return friends, nil
+		}
+	}
+
+	cfg := config.Get()
+	if cfg.SteamAPIKey == "" {
+		return nil, fmt.Errorf("STEAM_API_KEY not configured")
+	}
+
+	params := url.Values{
+		"key":          {cfg.SteamAPIKey},
+		"steamid":      {steamID},
+		"relationship": {"friend"},
+	}
+
+	reqURL := fmt.Sprintf("%s?%s", steamFriendListURL, params.Encode())
+	resp, err := s.get(reqURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch friend list: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("steam API returned status %d", resp.StatusCode)
+	}
+
+	var data models.FriendListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	friends := make([]models.Friend, 0, len(data.Friendslist.Friends))
+	friendIDs := make([]string, 0, len(data.Friendslist.Friends))
+	for _, f := range data.Friendslist.Friends {
+		friends = append(friends, models.Friend{
+			SteamID:      f.Steamid,
+			Relationship: f.Relationship,
+			FriendSince:  f.FriendSince,
+		})
+		friendIDs = append(friendIDs, f.Steamid)
+	}
+
+	// 批量补充好友的用户名/头像等资料，单个好友的资料缺失不应该让整个好友列表失败
+	if summaries, err := s.GetPlayerSummaries(friendIDs); err != nil {
+		log.Printf("Failed to enrich friends with player summaries: %v", err)
+	} else {
+		byID := make(map[string]models.PlayerSummary, len(summaries))
+		for _, summary := range summaries {
+			byID[summary.SteamID] = summary
+		}
+		for i, f := range friends {
+			if summary, ok := byID[f.SteamID]; ok {
+				friends[i].Username = summary.Username
+				friends[i].Avatar = summary.Avatar
+				friends[i].ProfileURL = summary.ProfileURL
+				friends[i].PersonaState = summary.PersonaState
+			}
+		}
+	}
+
+	if encoded, err := json.Marshal(friends); err == nil {
+		s.cache.Set(ctx, cacheKey, encoded, friendsCacheDuration)
+	}
+
+	return friends, nil
+}
+
+// GetPlayerSummaries 批量获取玩家资料（带缓存，单次请求最多 100 个 steamid）
+func (s *SteamService) GetPlayerSummaries(steamIDs []string) ([]models.PlayerSummary, error) {
+	if len(steamIDs) == 0 {
+		return []models.PlayerSummary{}, nil
+	}
+
+	ctx := context.Background()
+	cacheKey := fmt.Sprintf("gg:summaries:%s", strings.Join(steamIDs, ","))
+
+	if cached, ok := s.cache.Get(ctx, cacheKey); ok {
+		var summaries []models.PlayerSummary
+		if err := json.Unmarshal(cached, &summaries); err == nil {
+			return summaries, nil
+		}
+	}
+
+	cfg := config.Get()
+	if cfg.SteamAPIKey == "" {
+		return nil, fmt.Errorf("STEAM_API_KEY not configured")
+	}
+
+	summaries := make([]models.PlayerSummary, 0, len(steamIDs))
+
+	for i := 0; i < len(steamIDs); i += steamPlayerSummariesPage {
+		end := i + steamPlayerSummariesPage
+		if end > len(steamIDs) {
+			end = len(steamIDs)
+		}
+
+		params := url.Values{
+			"key":      {cfg.SteamAPIKey},
+			"steamids": {strings.Join(steamIDs[i:end], ",")},
+		}
+
+		reqURL := fmt.Sprintf("%s?%s", steamPlayerSummariesURL, params.Encode())
+		resp, err := s.get(reqURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch player summaries: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("steam API returned status %d", resp.StatusCode)
+		}
+
+		var data models.PlayerSummariesResponse
+		err = json.NewDecoder(resp.Body).Decode(&data)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		for _, p := range data.Response.Players {
+			summaries = append(summaries, models.PlayerSummary{
+				SteamID:      p.Steamid,
+				Username:     p.Personaname,
+				Avatar:       p.Avatarfull,
+				ProfileURL:   p.Profileurl,
+				PersonaState: p.Personastate,
+			})
+		}
+	}
+
+	if encoded, err := json.Marshal(summaries); err == nil {
+		s.cache.Set(ctx, cacheKey, encoded, summariesCacheDuration)
+	}
+
+	return summaries, nil
+}
+
+// GetOwnedGames 获取指定用户的游戏库（带缓存）
+func (s *SteamService) GetOwnedGames(steamID string) ([]models.OwnedGame, error) {
+	ctx := context.Background()
+	cacheKey := fmt.Sprintf("gg:library:%s", steamID)
+
+	if cached, ok := s.cache.Get(ctx, cacheKey); ok {
+		var games []models.OwnedGame
+		if err := json.Unmarshal(cached, &games); err == nil {
+			return games, nil
+		}
+	}
+
+	cfg := config.Get()
+	if cfg.SteamAPIKey == "" {
+		return nil, fmt.Errorf("STEAM_API_KEY not configured")
+	}
+
+	params := url.Values{
+		"key":                       {cfg.SteamAPIKey},
+		"steamid":                   {steamID},
+		"include_appinfo":           {"1"},
+		"include_played_free_games": {"1"},
+	}
+
+	reqURL := fmt.Sprintf("%s?%s", steamOwnedGamesURL, params.Encode())
+	resp, err := s.get(reqURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch owned games: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("steam API returned status %d", resp.StatusCode)
+	}
+
+	var data models.OwnedGamesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	games := make([]models.OwnedGame, 0, len(data.Response.Games))
+	for _, g := range data.Response.Games {
+		games = append(games, models.OwnedGame{
+			AppID:           g.Appid,
+			Name:            g.Name,
+			PlaytimeForever: g.PlaytimeForever,
+			PlaytimeRecent:  g.Playtime2Weeks,
+			IconURL:         fmt.Sprintf("https://media.steampowered.com/steamcommunity/public/images/apps/%d/%s.jpg", g.Appid, g.ImgIconURL),
+		})
+	}
+
+	if encoded, err := json.Marshal(games); err == nil {
+		s.cache.Set(ctx, cacheKey, encoded, libraryCacheDuration)
+	}
+
+	return games, nil
+}
+
+// GetPriceOverview 获取指定 appid 当前的价格与折扣信息，供 Scheduler 判断是否打折
+func (s *SteamService) GetPriceOverview(appID int) (*models.PriceOverview, error) {
+	reqURL := fmt.Sprintf("%s?appids=%d&filters=price_overview&cc=us", steamAppDetailsURL, appID)
+
+	resp, err := s.get(reqURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch app details: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("steam API returned status %d", resp.StatusCode)
+	}
+
+	var data models.AppDetailsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	entry, ok := data[strconv.Itoa(appID)]
+	if !ok || !entry.Success {
+		return nil, fmt.Errorf("no app details for app %d", appID)
+	}
+
+	return &models.PriceOverview{
+		Currency:        entry.Data.PriceOverview.Currency,
+		Initial:         entry.Data.PriceOverview.Initial,
+		Final:           entry.Data.PriceOverview.Final,
+		DiscountPercent: entry.Data.PriceOverview.DiscountPercent,
+	}, nil
+}
+
+// GetLatestNews 获取指定 appid 最新的一条新闻，供 Scheduler 判断是否有新新闻
+func (s *SteamService) GetLatestNews(appID int) (*models.NewsItem, error) {
+	params := url.Values{
+		"appid": {strconv.Itoa(appID)},
+		"count": {"1"},
+	}
+
+	reqURL := fmt.Sprintf("%s?%s", steamNewsForAppURL, params.Encode())
+	resp, err := s.get(reqURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch news: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("steam API returned status %d", resp.StatusCode)
+	}
+
+	var data models.NewsForAppResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(data.Appnews.Newsitems) == 0 {
+		return nil, nil
+	}
+
+	item := data.Appnews.Newsitems[0]
+	return &models.NewsItem{
+		GID:   item.Gid,
+		Title: item.Title,
+		URL:   item.URL,
+		Date:  item.Date,
+	}, nil
+}
+
+// GetAppList 分页拉取 Steam 全量 App 列表（IStoreService/GetAppList），供 GameIndex 构建本地索引使用
+func (s *SteamService) GetAppList() ([]models.SteamApp, error) {
+	cfg := config.Get()
+
+	apps := make([]models.SteamApp, 0, appListPageMaxResults)
+	lastAppID := 0
+
+	for {
+		params := url.Values{
+			"max_results": {strconv.Itoa(appListPageMaxResults)},
+			"last_appid":  {strconv.Itoa(lastAppID)},
+		}
+		if cfg.SteamAPIKey != "" {
+			params.Set("key", cfg.SteamAPIKey)
+		}
+
+		reqURL := fmt.Sprintf("%s?%s", steamAppListURL, params.Encode())
+		resp, err := s.get(reqURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch app list: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("steam API returned status %d", resp.StatusCode)
+		}
+
+		var data models.SteamAppListResponse
+		err = json.NewDecoder(resp.Body).Decode(&data)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		apps = append(apps, data.Response.Apps...)
+
+		if !data.Response.HaveMoreResults {
+			break
+		}
+		lastAppID = data.Response.LastAppID
+	}
+
+	return apps, nil
+}
@@ -0,0 +1,177 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"game-gallery-backend/internal/config"
+)
+
+const subscriptionsBucket = "subscriptions"
+
+// Subscription 表示用户对某个 appid 的订阅，调度器据此判断是否需要推送通知
+type Subscription struct {
+	ID                  string    `json:"id"`
+	SteamID             string    `json:"steamId"`
+	AppID               int       `json:"appid"`
+	Threshold           int       `json:"threshold,omitempty"`
+	NotifyOnSale        bool      `json:"notifyOnSale,omitempty"`
+	NotifyOnNews        bool      `json:"notifyOnNews,omitempty"`
+	WebhookURL          string    `json:"webhookUrl,omitempty"`
+	LastPlayerCount     int       `json:"lastPlayerCount"`
+	LastDiscountPercent int       `json:"lastDiscountPercent"`
+	LastNewsGID         string    `json:"lastNewsGid,omitempty"`
+	CreatedAt           time.Time `json:"createdAt"`
+}
+
+// SubscriptionStore 基于 BoltDB 的订阅持久化存储
+type SubscriptionStore struct {
+	db *bbolt.DB
+}
+
+// NewSubscriptionStore 打开（或创建）指定路径下的订阅数据库
+func NewSubscriptionStore(path string) (*SubscriptionStore, error) {
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open subscription store: %w", err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(subscriptionsBucket))
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to init subscription store: %w", err)
+	}
+
+	return &SubscriptionStore{db: db}, nil
+}
+
+// Close 关闭底层数据库
+func (s *SubscriptionStore) Close() error {
+	return s.db.Close()
+}
+
+// Create 写入一条新订阅
+func (s *SubscriptionStore) Create(sub *Subscription) error {
+	return s.put(sub)
+}
+
+// Update 覆写一条已存在的订阅（调度器用于记录最近一次观测值）
+func (s *SubscriptionStore) Update(sub *Subscription) error {
+	return s.put(sub)
+}
+
+func (s *SubscriptionStore) put(sub *Subscription) error {
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return fmt.Errorf("failed to marshal subscription: %w", err)
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(subscriptionsBucket))
+		return b.Put([]byte(sub.ID), data)
+	})
+}
+
+// Delete 删除一条订阅，要求调用方确实是该订阅的所有者
+func (s *SubscriptionStore) Delete(id, steamID string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(subscriptionsBucket))
+
+		existing := b.Get([]byte(id))
+		if existing == nil {
+			return fmt.Errorf("subscription not found")
+		}
+
+		var sub Subscription
+		if err := json.Unmarshal(existing, &sub); err != nil {
+			return fmt.Errorf("failed to unmarshal subscription: %w", err)
+		}
+
+		if sub.SteamID != steamID {
+			return fmt.Errorf("subscription not found")
+		}
+
+		return b.Delete([]byte(id))
+	})
+}
+
+// ListBySteamID 返回指定用户的全部订阅
+func (s *SubscriptionStore) ListBySteamID(steamID string) ([]*Subscription, error) {
+	subs := make([]*Subscription, 0)
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(subscriptionsBucket))
+		return b.ForEach(func(_, v []byte) error {
+			var sub Subscription
+			if err := json.Unmarshal(v, &sub); err != nil {
+				return fmt.Errorf("failed to unmarshal subscription: %w", err)
+			}
+			if sub.SteamID == steamID {
+				subs = append(subs, &sub)
+			}
+			return nil
+		})
+	})
+
+	return subs, err
+}
+
+// All 返回全部订阅，供调度器批量处理
+func (s *SubscriptionStore) All() ([]*Subscription, error) {
+	subs := make([]*Subscription, 0)
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(subscriptionsBucket))
+		return b.ForEach(func(_, v []byte) error {
+			var sub Subscription
+			if err := json.Unmarshal(v, &sub); err != nil {
+				return fmt.Errorf("failed to unmarshal subscription: %w", err)
+			}
+			subs = append(subs, &sub)
+			return nil
+		})
+	})
+
+	return subs, err
+}
+
+// NewSubscriptionID 生成一个随机的订阅 ID
+func NewSubscriptionID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		// crypto/rand 读取失败的概率极低，退化为基于时间的 ID 以保证可用性
+		return fmt.Sprintf("sub-%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+var (
+	instance *SubscriptionStore
+	once     sync.Once
+	initErr  error
+)
+
+// GetSubscriptionStore 获取订阅存储单例，数据库文件位于 cfg.DataDir/subscriptions.db
+func GetSubscriptionStore() (*SubscriptionStore, error) {
+	once.Do(func() {
+		cfg := config.Get()
+		if err := os.MkdirAll(cfg.DataDir, 0755); err != nil {
+			initErr = fmt.Errorf("failed to create data dir: %w", err)
+			return
+		}
+		instance, initErr = NewSubscriptionStore(filepath.Join(cfg.DataDir, "subscriptions.db"))
+	})
+
+	return instance, initErr
+}
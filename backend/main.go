@@ -26,12 +26,30 @@ func main() {
 	log.Println("Initializing Steam service...")
 	services.GetSteamService()
 
+	// 启动订阅调度器（每分钟巡检一次订阅的 appid）
+	if _, err := services.StartScheduler(); err != nil {
+		log.Printf("Warning: Failed to start subscription scheduler: %v", err)
+	}
+
+	// 启动本地游戏索引（加载/刷新全量 App 列表，每天自动刷新一次）
+	if _, err := services.StartGameIndexRefresh(); err != nil {
+		log.Printf("Warning: Failed to start game index refresh: %v", err)
+	}
+
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /api/health", api.HealthHandler)
 	mux.HandleFunc("GET /api/auth/steam", api.SteamLoginHandler)
 	mux.HandleFunc("GET /api/auth/steam/callback", api.SteamCallbackHandler)
 	mux.HandleFunc("GET /api/games/search", api.GameSearchHandler)
 	mux.HandleFunc("GET /api/games/cache-stats", api.GameCacheStatsHandler)
+	mux.HandleFunc("GET /api/games/suggest", api.GameSuggestHandler)
+	mux.HandleFunc("GET /api/users/resolve", api.ResolveVanityHandler)
+	mux.HandleFunc("GET /api/users/{steamid}/friends", api.UserFriendsHandler)
+	mux.HandleFunc("GET /api/users/{steamid}/library", api.UserLibraryHandler)
+	mux.HandleFunc("POST /api/subscriptions", api.CreateSubscriptionHandler)
+	mux.HandleFunc("GET /api/subscriptions", api.ListSubscriptionsHandler)
+	mux.HandleFunc("DELETE /api/subscriptions", api.DeleteSubscriptionHandler)
+	mux.HandleFunc("GET /api/subscriptions/stream", api.SubscriptionStreamHandler)
 
 	handler := recoveryMiddleware(loggingMiddleware(corsMiddleware(mux)))
 